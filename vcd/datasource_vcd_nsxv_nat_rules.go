@@ -0,0 +1,108 @@
+package vcd
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// datasourceVcdNsxvNatRules returns the current ordered list of user NAT rules on an NSX-V edge
+// gateway, so that it can be fed into `vcd_nsxv_nat_rule_order.rule_ids` without the caller having
+// to track rule IDs by hand.
+func datasourceVcdNsxvNatRules() *schema.Resource {
+	return &schema.Resource{
+		Read: datasourceVcdNsxvNatRulesRead,
+
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"edge_gateway": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Edge gateway name in which NAT rules are located",
+			},
+			"rule_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Ordered list of user NAT rule IDs currently configured on the edge gateway",
+			},
+			"rules": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "NAT rule ID",
+						},
+						"action": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "NAT rule action, such as 'dnat' or 'snat'",
+						},
+						"enabled": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the rule is enabled",
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "NAT rule description",
+						},
+					},
+				},
+				Description: "Ordered list of user NAT rules currently configured on the edge gateway",
+			},
+		},
+	}
+}
+
+func datasourceVcdNsxvNatRulesRead(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
+	if err != nil {
+		return fmt.Errorf("unable to find edge gateway: %s", err)
+	}
+
+	allRules, err := edgeGateway.GetNsxvNatRules()
+	if err != nil {
+		return fmt.Errorf("error reading NAT rules: %s", err)
+	}
+
+	var ruleIds []string
+	var rules []map[string]interface{}
+	for _, rule := range allRules {
+		if rule.RuleType != "user" {
+			continue
+		}
+		ruleIds = append(ruleIds, rule.ID)
+		rules = append(rules, map[string]interface{}{
+			"id":          rule.ID,
+			"action":      rule.Action,
+			"enabled":     rule.Enabled,
+			"description": rule.Description,
+		})
+	}
+
+	if err := d.Set("rule_ids", ruleIds); err != nil {
+		return err
+	}
+	if err := d.Set("rules", rules); err != nil {
+		return err
+	}
+
+	d.SetId(edgeGateway.EdgeGateway.Name)
+	return nil
+}
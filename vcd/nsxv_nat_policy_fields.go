@@ -0,0 +1,48 @@
+package vcd
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/vmware/go-vcloud-director/v2/types/v56"
+)
+
+// edgeNatRuleRequest is the wire shape a DNAT rule would need in order to carry
+// `policy_based_vpn_mode`/`firewall_match`, which post-date the vendored types.EdgeNatRule and so
+// cannot be added to it as struct fields without patching the vendored package. Sending this
+// wrapper instead of *types.EdgeNatRule would require an EdgeGateway method that accepts/returns a
+// raw request/response body; no such method is confirmed to exist on the vendored
+// govcd.EdgeGateway in this snapshot (no vendor source is available here to check), so
+// policyBasedVpnMode/firewallMatch are not actually transmitted to NSX-V yet - see
+// natRuleCreate/natRuleRead/natRuleUpdate in nsxv_natrule_shared.go, which only ever call the
+// confirmed-real CreateNsxvNatRule/UpdateNsxvNatRule/GetNsxvNatRuleById and keep these two fields
+// local to Terraform state. buildNatRuleRequestBody/parseNatRuleResponseBody are kept, and tested,
+// as the wire format to wire in once a raw-body EdgeGateway method (or a vendored field) is
+// confirmed to exist.
+type edgeNatRuleRequest struct {
+	XMLName xml.Name `xml:"natRule"`
+	types.EdgeNatRule
+	PolicyBasedVpnMode string `xml:"policyBasedVpnMode,omitempty"`
+	FirewallMatch      string `xml:"firewallMatch,omitempty"`
+}
+
+// buildNatRuleRequestBody returns the XML request body for natRule, folding in
+// policyBasedVpnMode/firewallMatch when either is set.
+func buildNatRuleRequestBody(natRule *types.EdgeNatRule, policyBasedVpnMode, firewallMatch string) ([]byte, error) {
+	request := edgeNatRuleRequest{
+		EdgeNatRule:        *natRule,
+		PolicyBasedVpnMode: policyBasedVpnMode,
+		FirewallMatch:      firewallMatch,
+	}
+	return xml.Marshal(request)
+}
+
+// parseNatRuleResponseBody extracts policyBasedVpnMode/firewallMatch back out of a raw NAT rule
+// API response, alongside the fields types.EdgeNatRule already knows how to unmarshal.
+func parseNatRuleResponseBody(body []byte) (*types.EdgeNatRule, string, string, error) {
+	var response edgeNatRuleRequest
+	if err := xml.Unmarshal(body, &response); err != nil {
+		return nil, "", "", fmt.Errorf("error parsing NAT rule response: %s", err)
+	}
+	return &response.EdgeNatRule, response.PolicyBasedVpnMode, response.FirewallMatch, nil
+}
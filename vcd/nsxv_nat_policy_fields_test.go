@@ -0,0 +1,58 @@
+package vcd
+
+import (
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v2/types/v56"
+)
+
+// TestBuildAndParseNatRuleRequestBody proves that policy_based_vpn_mode and firewall_match survive
+// a round trip through buildNatRuleRequestBody/parseNatRuleResponseBody, since neither field exists
+// on the vendored types.EdgeNatRule and would otherwise be silently dropped.
+func TestBuildAndParseNatRuleRequestBody(t *testing.T) {
+	natRule := &types.EdgeNatRule{
+		ID:          "natRule-1",
+		Action:      "dnat",
+		Description: "test rule",
+	}
+
+	body, err := buildNatRuleRequestBody(natRule, "bypass", "match_internal_address")
+	if err != nil {
+		t.Fatalf("error building request body: %s", err)
+	}
+
+	parsedRule, policyBasedVpnMode, firewallMatch, err := parseNatRuleResponseBody(body)
+	if err != nil {
+		t.Fatalf("error parsing response body: %s", err)
+	}
+
+	if policyBasedVpnMode != "bypass" {
+		t.Errorf("expected policy_based_vpn_mode 'bypass', got '%s'", policyBasedVpnMode)
+	}
+	if firewallMatch != "match_internal_address" {
+		t.Errorf("expected firewall_match 'match_internal_address', got '%s'", firewallMatch)
+	}
+	if parsedRule.ID != natRule.ID || parsedRule.Description != natRule.Description {
+		t.Errorf("expected underlying EdgeNatRule fields to survive the round trip, got %+v", parsedRule)
+	}
+}
+
+// TestBuildAndParseNatRuleRequestBodyEmpty proves that a rule which never sets either field reads
+// back two empty strings rather than placeholder values.
+func TestBuildAndParseNatRuleRequestBodyEmpty(t *testing.T) {
+	natRule := &types.EdgeNatRule{ID: "natRule-2", Action: "dnat"}
+
+	body, err := buildNatRuleRequestBody(natRule, "", "")
+	if err != nil {
+		t.Fatalf("error building request body: %s", err)
+	}
+
+	_, policyBasedVpnMode, firewallMatch, err := parseNatRuleResponseBody(body)
+	if err != nil {
+		t.Fatalf("error parsing response body: %s", err)
+	}
+
+	if policyBasedVpnMode != "" || firewallMatch != "" {
+		t.Errorf("expected both fields empty, got policyBasedVpnMode=%q firewallMatch=%q", policyBasedVpnMode, firewallMatch)
+	}
+}
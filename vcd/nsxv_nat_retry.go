@@ -0,0 +1,62 @@
+package vcd
+
+import (
+	"strings"
+	"time"
+)
+
+// defaultNatRetryTimeout is used when VCDClient.MaxRetryTimeout is zero.
+// natRuleCreate/natRuleUpdate/natRuleDelete read MaxRetryTimeout off the client and pass it to
+// withNatRetry around their CreateNsxvNatRule/UpdateNsxvNatRule/DeleteNsxvNatRule calls, the same
+// way resourceVcdNsxvNatRulesetCreateUpdate/Delete already do for the ruleset resource.
+const defaultNatRetryTimeout = 120
+
+// withNatRetry retries op with exponential backoff until it succeeds, returns a non-retryable
+// error, or maxRetrySeconds elapses. vCD serializes edge gateway configuration changes, so a
+// second NAT rule create/update/delete landing on the same edge gateway while another task still
+// holds its lock fails with a "busy edge gateway" style 400 rather than queuing - this is the only
+// way for concurrent Terraform runs against a shared edge gateway to succeed reliably.
+func withNatRetry(maxRetrySeconds int, op func() error) error {
+	if maxRetrySeconds <= 0 {
+		maxRetrySeconds = defaultNatRetryTimeout
+	}
+
+	deadline := time.Now().Add(time.Duration(maxRetrySeconds) * time.Second)
+	backoff := 1 * time.Second
+
+	for {
+		err := op()
+		if err == nil {
+			return nil
+		}
+
+		if !isRetryableEdgeGatewayError(err) || time.Now().After(deadline) {
+			return err
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > 30*time.Second {
+			backoff = 30 * time.Second
+		}
+	}
+}
+
+// isRetryableEdgeGatewayError reports whether err looks like the transient "edge gateway is busy"
+// or concurrent-edit error vCD returns when another task already holds the ESG lock.
+func isRetryableEdgeGatewayError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	message := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(message, "is busy"),
+		strings.Contains(message, "is currently being used"),
+		strings.Contains(message, "another operation is in progress"),
+		strings.Contains(message, "could not obtain lock"):
+		return true
+	default:
+		return false
+	}
+}
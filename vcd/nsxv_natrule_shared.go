@@ -0,0 +1,213 @@
+package vcd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+
+	"github.com/vmware/go-vcloud-director/v2/govcd"
+	"github.com/vmware/go-vcloud-director/v2/types/v56"
+)
+
+// getNatRuleFunc builds a types.EdgeNatRule from Terraform configuration, as getDnatRule does for
+// `vcd_nsxv_dnat`.
+type getNatRuleFunc func(d *schema.ResourceData, edgeGateway govcd.EdgeGateway) (*types.EdgeNatRule, error)
+
+// setNatRuleDataFunc writes a types.EdgeNatRule back into the statefile, as setDnatRuleData does
+// for `vcd_nsxv_dnat`. policyBasedVpnMode/firewallMatch are threaded alongside natRule, rather than
+// read off it, because they are not part of the vendored type and are not transmitted to NSX-V -
+// see nsxv_nat_policy_fields.go.
+type setNatRuleDataFunc func(d *schema.ResourceData, natRule *types.EdgeNatRule, edgeGateway govcd.EdgeGateway, policyBasedVpnMode, firewallMatch string) error
+
+// natRuleCreate returns the schema.CreateFunc shared by `vcd_nsxv_dnat`/`vcd_nsxv_snat`. The
+// actual create call is wrapped in withNatRetry, keyed off the provider's `max_retry_timeout`
+// (VCDClient.MaxRetryTimeout), because creating several rules on the same edge gateway at once
+// commonly collides with another task still holding the ESG lock.
+func natRuleCreate(action string, setData setNatRuleDataFunc, getRule getNatRuleFunc) schema.CreateFunc {
+	return func(d *schema.ResourceData, meta interface{}) error {
+		vcdClient := meta.(*VCDClient)
+		edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
+		if err != nil {
+			return fmt.Errorf("unable to find edge gateway: %s", err)
+		}
+
+		natRule, err := getRule(d, edgeGateway)
+		if err != nil {
+			return fmt.Errorf("error building %s rule: %s", action, err)
+		}
+		natRule.Action = action
+
+		aboveRuleId, err := resolveAboveRuleId(d, edgeGateway)
+		if err != nil {
+			return err
+		}
+		natRule.AboveRuleID = aboveRuleId
+
+		// policy_based_vpn_mode/firewall_match are not transmitted to NSX-V - see
+		// nsxv_nat_policy_fields.go - so they are read straight from configuration rather than
+		// from the created rule.
+		policyBasedVpnMode := d.Get("policy_based_vpn_mode").(string)
+		firewallMatch := d.Get("firewall_match").(string)
+
+		var created *types.EdgeNatRule
+		err = withNatRetry(vcdClient.MaxRetryTimeout, func() error {
+			var createErr error
+			created, createErr = edgeGateway.CreateNsxvNatRule(natRule)
+			return createErr
+		})
+		if err != nil {
+			return fmt.Errorf("error creating %s rule: %s", action, err)
+		}
+
+		d.SetId(created.ID)
+		return setData(d, created, edgeGateway, policyBasedVpnMode, firewallMatch)
+	}
+}
+
+// natRuleRead returns the schema.ReadFunc shared by `vcd_nsxv_dnat`/`vcd_nsxv_snat`. idField names
+// the schema attribute holding the rule ID to look up - normally "id" (d.Id() itself), kept as a
+// parameter so import paths that have not yet set d.Id() can read it from elsewhere.
+func natRuleRead(idField, action string, setData setNatRuleDataFunc) schema.ReadFunc {
+	return func(d *schema.ResourceData, meta interface{}) error {
+		vcdClient := meta.(*VCDClient)
+		edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
+		if err != nil {
+			d.SetId("")
+			return nil
+		}
+
+		ruleId := d.Id()
+		if idField != "id" {
+			ruleId = d.Get(idField).(string)
+		}
+
+		natRule, err := edgeGateway.GetNsxvNatRuleById(ruleId)
+		if govcd.IsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading %s rule: %s", action, err)
+		}
+		if natRule.Action != action {
+			d.SetId("")
+			return nil
+		}
+
+		// policy_based_vpn_mode/firewall_match are not transmitted to NSX-V - see
+		// nsxv_nat_policy_fields.go - so Read has nothing to fetch for them and leaves the
+		// configured values in state untouched.
+		policyBasedVpnMode := d.Get("policy_based_vpn_mode").(string)
+		firewallMatch := d.Get("firewall_match").(string)
+
+		d.SetId(natRule.ID)
+		return setData(d, natRule, edgeGateway, policyBasedVpnMode, firewallMatch)
+	}
+}
+
+// natRuleUpdate returns the schema.UpdateFunc shared by `vcd_nsxv_dnat`/`vcd_nsxv_snat`, retrying
+// the update call the same way natRuleCreate does.
+func natRuleUpdate(action string, setData setNatRuleDataFunc, getRule getNatRuleFunc) schema.UpdateFunc {
+	return func(d *schema.ResourceData, meta interface{}) error {
+		vcdClient := meta.(*VCDClient)
+		edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
+		if err != nil {
+			return fmt.Errorf("unable to find edge gateway: %s", err)
+		}
+
+		natRule, err := getRule(d, edgeGateway)
+		if err != nil {
+			return fmt.Errorf("error building %s rule: %s", action, err)
+		}
+		natRule.ID = d.Id()
+		natRule.Action = action
+
+		// policy_based_vpn_mode/firewall_match are not transmitted to NSX-V - see
+		// nsxv_nat_policy_fields.go - so they are read straight from configuration rather than
+		// from the updated rule.
+		policyBasedVpnMode := d.Get("policy_based_vpn_mode").(string)
+		firewallMatch := d.Get("firewall_match").(string)
+
+		var updated *types.EdgeNatRule
+		err = withNatRetry(vcdClient.MaxRetryTimeout, func() error {
+			var updateErr error
+			updated, updateErr = edgeGateway.UpdateNsxvNatRule(natRule)
+			return updateErr
+		})
+		if err != nil {
+			return fmt.Errorf("error updating %s rule: %s", action, err)
+		}
+
+		return setData(d, updated, edgeGateway, policyBasedVpnMode, firewallMatch)
+	}
+}
+
+// natRuleDelete returns the schema.DeleteFunc shared by `vcd_nsxv_dnat`/`vcd_nsxv_snat`, retrying
+// the delete call the same way natRuleCreate does.
+func natRuleDelete(action string) schema.DeleteFunc {
+	return func(d *schema.ResourceData, meta interface{}) error {
+		vcdClient := meta.(*VCDClient)
+		edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
+		if err != nil {
+			return fmt.Errorf("unable to find edge gateway: %s", err)
+		}
+
+		return withNatRetry(vcdClient.MaxRetryTimeout, func() error {
+			return edgeGateway.DeleteNsxvNatRule(d.Id())
+		})
+	}
+}
+
+// natRuleImport returns the schema.StateFunc shared by `vcd_nsxv_dnat`/`vcd_nsxv_snat`. The import
+// ID is expected in "org.vdc.edge_gateway.rule_id" form, consistent with this provider's other
+// nested-resource imports.
+func natRuleImport(action string) schema.StateFunc {
+	return func(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+		parts := strings.Split(d.Id(), ".")
+		if len(parts) != 4 {
+			return nil, fmt.Errorf(
+				"resource id must be specified as org.vdc.edge_gateway.rule_id, got: %s", d.Id())
+		}
+
+		_ = d.Set("org", parts[0])
+		_ = d.Set("vdc", parts[1])
+		_ = d.Set("edge_gateway", parts[2])
+		d.SetId(parts[3])
+
+		return []*schema.ResourceData{d}, nil
+	}
+}
+
+// resolveAboveRuleId returns the rule ID that a new rule should be inserted above: the literal
+// `above_rule_id`, or, when `below_rule_id` is set instead, the ID of the rule that currently sits
+// directly *below* it - inserting above that neighbor is what lands the new rule directly below
+// `below_rule_id`, as documented on that field. Returns an empty string when neither is set, or
+// when `below_rule_id` names the last rule; a rule created with no above rule ID is appended at the
+// end of the ruleset.
+func resolveAboveRuleId(d *schema.ResourceData, edgeGateway govcd.EdgeGateway) (string, error) {
+	if aboveRuleId, ok := d.GetOk("above_rule_id"); ok {
+		return aboveRuleId.(string), nil
+	}
+
+	belowRuleId, ok := d.GetOk("below_rule_id")
+	if !ok {
+		return "", nil
+	}
+
+	rules, err := edgeGateway.GetNsxvNatRules()
+	if err != nil {
+		return "", fmt.Errorf("error resolving below_rule_id: %s", err)
+	}
+
+	for i, rule := range rules {
+		if rule.ID == belowRuleId.(string) {
+			if i+1 < len(rules) {
+				return rules[i+1].ID, nil
+			}
+			return "", nil
+		}
+	}
+
+	return "", fmt.Errorf("below_rule_id '%s' not found in edge gateway NAT ruleset", belowRuleId.(string))
+}
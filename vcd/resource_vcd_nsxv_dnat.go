@@ -3,6 +3,7 @@ package vcd
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
@@ -20,6 +21,7 @@ func resourceVcdNsxvDnat() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: natRuleImport("dnat"),
 		},
+		CustomizeDiff: validateNatRuleProtocolFields,
 
 		Schema: map[string]*schema.Schema{
 			"org": {
@@ -67,10 +69,21 @@ func resourceVcdNsxvDnat() *schema.Resource {
 				Description: "Optional. Allows to set custom rule tag",
 			},
 			"above_rule_id": &schema.Schema{
-				Type:        schema.TypeString,
-				ForceNew:    true,
-				Optional:    true,
-				Description: "This firewall rule will be inserted above the referred one",
+				Type:          schema.TypeString,
+				ForceNew:      true,
+				Optional:      true,
+				ConflictsWith: []string{"below_rule_id"},
+				Description:   "This firewall rule will be inserted above the referred one",
+			},
+			"below_rule_id": &schema.Schema{
+				Type:          schema.TypeString,
+				ForceNew:      true,
+				Optional:      true,
+				ConflictsWith: []string{"above_rule_id"},
+				Description: "This NAT rule will be inserted below the referred one. Resolved, " +
+					"at create time, to the ID of whatever rule currently sits directly below " +
+					"`below_rule_id` and used as this rule's `above_rule_id`; only takes effect " +
+					"on creation, same as `above_rule_id`",
 			},
 			"enabled": &schema.Schema{
 				Type:        schema.TypeBool,
@@ -93,19 +106,33 @@ func resourceVcdNsxvDnat() *schema.Resource {
 				Description: "NAT rule description",
 			},
 			"original_address": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: false,
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      false,
+				ConflictsWith: []string{"original_address_groups"},
 				Description: "Original address or address range. This is the " +
-					"the destination address for DNAT rules.",
+					"the destination address for DNAT rules. Exactly one of `original_address` or " +
+					"`original_address_groups` must be set",
+			},
+			"original_address_groups": &schema.Schema{
+				Type:          schema.TypeList,
+				Optional:      true,
+				ForceNew:      false,
+				ConflictsWith: []string{"original_address"},
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Description: "Names or IDs of existing `vcd_nsxv_ip_set` objects whose members are " +
+					"used as the original address. Exactly one of `original_address` or " +
+					"`original_address_groups` must be set",
 			},
 			"protocol": &schema.Schema{
 				Type:             schema.TypeString,
 				Optional:         true,
 				ForceNew:         false,
 				DiffSuppressFunc: suppressWordToEmptyString("any"),
-				ValidateFunc:     validateCase("lower"),
-				Description:      "Protocol. Such as 'tcp', 'udp', 'icmp', 'any'",
+				ValidateFunc:     validateNatProtocol,
+				Description: "Protocol. One of 'tcp', 'udp', 'icmp', 'any', or a numeric IANA " +
+					"protocol number 1-252 (excluding the aliases 1, 6, 17 - use the name instead)",
 			},
 			"icmp_type": &schema.Schema{
 				Type:         schema.TypeString,
@@ -121,21 +148,57 @@ func resourceVcdNsxvDnat() *schema.Resource {
 				Type:             schema.TypeString,
 				Optional:         true,
 				ForceNew:         false,
-				DiffSuppressFunc: suppressWordToEmptyString("any"),
-				Description:      "Original port. This is the destination port for DNAT rules",
+				DiffSuppressFunc: suppressNatPortRangeNormalization,
+				ValidateFunc:     validateNatPortRange,
+				Description: "Original port. This is the destination port for DNAT rules. One " +
+					"of 'any', a single port, or a 'start-end' range",
 			},
 			"translated_address": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				ForceNew:    false,
-				Description: "Translated address or address range",
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      false,
+				ConflictsWith: []string{"translated_address_groups"},
+				Description:   "Translated address or address range",
+			},
+			"translated_address_groups": &schema.Schema{
+				Type:          schema.TypeList,
+				Optional:      true,
+				ForceNew:      false,
+				ConflictsWith: []string{"translated_address"},
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Description: "Names or IDs of existing `vcd_nsxv_ip_set` objects whose members are " +
+					"used as the translated address",
 			},
 			"translated_port": &schema.Schema{
 				Type:             schema.TypeString,
 				Optional:         true,
 				ForceNew:         false,
-				DiffSuppressFunc: suppressWordToEmptyString("any"),
-				Description:      "Translated port",
+				DiffSuppressFunc: suppressNatPortRangeNormalization,
+				ValidateFunc:     validateNatPortRange,
+				Description:      "Translated port. One of 'any', a single port, or a 'start-end' range",
+			},
+			"policy_based_vpn_mode": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     false,
+				ValidateFunc: validation.StringInSlice([]string{"bypass", "match"}, false),
+				Description: "How this rule interacts with policy based VPN tunnels. One of " +
+					"'bypass' (NAT is skipped for traffic matched by a policy based VPN) or 'match' " +
+					"(NAT applies even when the traffic is also selected by a policy based VPN). Not " +
+					"currently sent to the NSX-V API - see the edgeNatRuleRequest doc comment in " +
+					"nsxv_nat_policy_fields.go - this value is tracked in Terraform state only",
+			},
+			"firewall_match": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: false,
+				ValidateFunc: validation.StringInSlice([]string{
+					"match_external_address", "match_internal_address", "bypass"}, false),
+				Description: "How the firewall matches traffic handled by this rule. One of " +
+					"'match_external_address', 'match_internal_address' or 'bypass'. Not currently " +
+					"sent to the NSX-V API - see the edgeNatRuleRequest doc comment in " +
+					"nsxv_nat_policy_fields.go - this value is tracked in Terraform state only",
 			},
 		},
 	}
@@ -152,16 +215,28 @@ func getDnatRule(d *schema.ResourceData, edgeGateway govcd.EdgeGateway) (*types.
 		return nil, err
 	}
 
+	originalAddress, err := resolveNatAddressOrGroups(
+		d, "original_address", "original_address_groups", edgeGateway)
+	if err != nil {
+		return nil, err
+	}
+
+	translatedAddress, err := resolveNatAddressOrGroups(
+		d, "translated_address", "translated_address_groups", edgeGateway)
+	if err != nil {
+		return nil, err
+	}
+
 	natRule := &types.EdgeNatRule{
 		Enabled:           d.Get("enabled").(bool),
 		LoggingEnabled:    d.Get("logging_enabled").(bool),
 		Description:       d.Get("description").(string),
 		Vnic:              vnicIndex,
-		OriginalAddress:   d.Get("original_address").(string),
+		OriginalAddress:   originalAddress,
 		Protocol:          d.Get("protocol").(string),
 		IcmpType:          d.Get("icmp_type").(string),
 		OriginalPort:      d.Get("original_port").(string),
-		TranslatedAddress: d.Get("translated_address").(string),
+		TranslatedAddress: translatedAddress,
 		TranslatedPort:    d.Get("translated_port").(string),
 	}
 
@@ -172,8 +247,36 @@ func getDnatRule(d *schema.ResourceData, edgeGateway govcd.EdgeGateway) (*types.
 	return natRule, nil
 }
 
-// setDnatRuleData is responsible for setting DNAT rule data into the statefile
-func setDnatRuleData(d *schema.ResourceData, natRule *types.EdgeNatRule, edgeGateway govcd.EdgeGateway) error {
+// resolveNatAddressOrGroups returns the literal address from addressKey if set, or else resolves
+// the `vcd_nsxv_ip_set` names/IDs listed under groupsKey to a comma-separated string of their
+// members, as expected by types.EdgeNatRule's address fields.
+func resolveNatAddressOrGroups(d *schema.ResourceData, addressKey, groupsKey string, edgeGateway govcd.EdgeGateway) (string, error) {
+	if address, ok := d.GetOk(addressKey); ok {
+		return address.(string), nil
+	}
+
+	groups := d.Get(groupsKey).([]interface{})
+	if len(groups) == 0 {
+		return "", fmt.Errorf("one of '%s' or '%s' must be set", addressKey, groupsKey)
+	}
+
+	var members []string
+	for _, raw := range groups {
+		ipSet, err := edgeGateway.GetNsxvIpSetByNameOrId(raw.(string))
+		if err != nil {
+			return "", fmt.Errorf("error looking up IP set '%s': %s", raw.(string), err)
+		}
+		members = append(members, ipSet.Value)
+	}
+
+	return strings.Join(members, ","), nil
+}
+
+// setDnatRuleData is responsible for setting DNAT rule data into the statefile. policyBasedVpnMode
+// and firewallMatch are threaded in separately rather than read off natRule, because they are not
+// part of the vendored types.EdgeNatRule and are not transmitted to NSX-V - see
+// nsxv_nat_policy_fields.go.
+func setDnatRuleData(d *schema.ResourceData, natRule *types.EdgeNatRule, edgeGateway govcd.EdgeGateway, policyBasedVpnMode, firewallMatch string) error {
 	networkName, resourceNetworkType, err := getNetworkNameTypeFromVnicIndex(*natRule.Vnic, edgeGateway)
 	if err != nil {
 		return err
@@ -192,13 +295,49 @@ func setDnatRuleData(d *schema.ResourceData, natRule *types.EdgeNatRule, edgeGat
 	_ = d.Set("enabled", natRule.Enabled)
 	_ = d.Set("logging_enabled", natRule.LoggingEnabled)
 	_ = d.Set("description", natRule.Description)
-	_ = d.Set("original_address", natRule.OriginalAddress)
+	setNatAddressOrGroups(d, "original_address", "original_address_groups", natRule.OriginalAddress, edgeGateway)
 	_ = d.Set("protocol", natRule.Protocol)
 	_ = d.Set("icmp_type", natRule.IcmpType)
 	_ = d.Set("original_port", natRule.OriginalPort)
-	_ = d.Set("translated_address", natRule.TranslatedAddress)
+	setNatAddressOrGroups(d, "translated_address", "translated_address_groups", natRule.TranslatedAddress, edgeGateway)
 	_ = d.Set("translated_port", natRule.TranslatedPort)
+	_ = d.Set("policy_based_vpn_mode", policyBasedVpnMode)
+	_ = d.Set("firewall_match", firewallMatch)
 	_ = d.Set("rule_type", natRule.RuleType)
 
 	return nil
 }
+
+// setNatAddressOrGroups writes address back into addressKey, unless the rule was configured with
+// groupsKey and the groups named there still resolve to exactly address, in which case addressKey
+// is cleared instead so a rule configured via address groups keeps reading that way. It only ever
+// checks the groups this resource was actually configured with - never reverse-guesses groups from
+// address alone - both because a literal address that happens to collide with some unrelated IP
+// set's value must not be rewritten into group form, and because resolveNatAddressOrGroups joins
+// each group's `Value` (itself possibly a comma-separated member list) with commas, which a blind
+// token split cannot unambiguously reverse. Lookup failures are not fatal: the rule still reads
+// correctly as a literal address.
+func setNatAddressOrGroups(d *schema.ResourceData, addressKey, groupsKey, address string, edgeGateway govcd.EdgeGateway) {
+	groups, ok := d.GetOk(groupsKey)
+	if !ok {
+		_ = d.Set(addressKey, address)
+		return
+	}
+
+	var members []string
+	for _, raw := range groups.([]interface{}) {
+		ipSet, err := edgeGateway.GetNsxvIpSetByNameOrId(raw.(string))
+		if err != nil {
+			_ = d.Set(addressKey, address)
+			return
+		}
+		members = append(members, ipSet.Value)
+	}
+
+	if strings.Join(members, ",") != address {
+		_ = d.Set(addressKey, address)
+		return
+	}
+
+	_ = d.Set(addressKey, "")
+}
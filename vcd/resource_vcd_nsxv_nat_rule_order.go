@@ -0,0 +1,198 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+
+	"github.com/vmware/go-vcloud-director/v2/govcd"
+	"github.com/vmware/go-vcloud-director/v2/types/v56"
+)
+
+// resourceVcdNsxvNatRuleOrder manages the relative order of NAT rules on an NSX-V edge gateway as
+// a single resource. It exists because `above_rule_id` on `vcd_nsxv_dnat`/`vcd_nsxv_snat` becomes
+// ambiguous once several rules are managed by independent resources and their ordering drifts
+// between applies. This resource rewrites the full ruleset in the order given, in one API call.
+// Rules that are not listed keep their relative order: they follow the listed rules, or, when
+// `below_rule_id` is set, those before `below_rule_id` stay above the listed rules.
+func resourceVcdNsxvNatRuleOrder() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVcdNsxvNatRuleOrderCreateUpdate,
+		Read:   resourceVcdNsxvNatRuleOrderRead,
+		Update: resourceVcdNsxvNatRuleOrderCreateUpdate,
+		Delete: resourceVcdNsxvNatRuleOrderDelete,
+
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"edge_gateway": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Edge gateway name in which NAT rules are located",
+			},
+			"rule_ids": {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Description: "Ordered list of existing `vcd_nsxv_dnat`/`vcd_nsxv_snat` rule IDs. " +
+					"Rules are rewritten to appear in this order, above any rule not listed here, " +
+					"or directly above `below_rule_id` when that is set",
+			},
+			"below_rule_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "If set, the ordered `rule_ids` block is inserted directly above the " +
+					"referred rule instead of at the top of the user ruleset",
+			},
+		},
+	}
+}
+
+// resourceVcdNsxvNatRuleOrderCreateUpdate rewrites the NAT ruleset on the edge gateway so that the
+// rules in `rule_ids` appear, in that order, either at the top of the user ruleset or directly
+// above `below_rule_id` when it is set.
+func resourceVcdNsxvNatRuleOrderCreateUpdate(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
+	if err != nil {
+		return fmt.Errorf("unable to find edge gateway: %s", err)
+	}
+
+	ruleIds := ruleIdsFromSchema(d.Get("rule_ids").([]interface{}))
+
+	orderedRules, err := reorderNsxvNatRules(edgeGateway, ruleIds, d.Get("below_rule_id").(string))
+	if err != nil {
+		return fmt.Errorf("error reordering NAT rules: %s", err)
+	}
+
+	if err := edgeGateway.UpdateNsxvNatRules(orderedRules); err != nil {
+		return fmt.Errorf("error applying reordered NAT ruleset: %s", err)
+	}
+
+	d.SetId(edgeGateway.EdgeGateway.Name)
+	return resourceVcdNsxvNatRuleOrderRead(d, meta)
+}
+
+func resourceVcdNsxvNatRuleOrderRead(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
+	if err != nil {
+		log.Printf("[DEBUG] could not find edge gateway: %s, removing from state", err)
+		d.SetId("")
+		return nil
+	}
+
+	rules, err := edgeGateway.GetNsxvNatRules()
+	if err != nil {
+		return fmt.Errorf("error reading NAT rules: %s", err)
+	}
+
+	// rule_ids is deliberately an ordered subset of the user ruleset ("above any rule not listed
+	// here"), so Read must not replace it with every user rule - that would permanently diff
+	// against a config that only orders some of them. Instead keep exactly the rules this
+	// resource was configured with, reporting their current live order so drift is visible.
+	configured := make(map[string]bool)
+	for _, id := range ruleIdsFromSchema(d.Get("rule_ids").([]interface{})) {
+		configured[id] = true
+	}
+
+	var currentOrder []string
+	for _, rule := range rules {
+		if rule.RuleType == "user" && configured[rule.ID] {
+			currentOrder = append(currentOrder, rule.ID)
+		}
+	}
+
+	return d.Set("rule_ids", currentOrder)
+}
+
+// resourceVcdNsxvNatRuleOrderDelete only removes the resource from state. The ordering resource
+// does not own the underlying rules, so deleting it must not delete or reorder them further.
+func resourceVcdNsxvNatRuleOrderDelete(d *schema.ResourceData, meta interface{}) error {
+	d.SetId("")
+	return nil
+}
+
+// reorderNsxvNatRules returns the full NAT ruleset with the rules named in ruleIds moved, in that
+// order, directly above belowRuleId (or above all other user rules if belowRuleId is empty). Rules
+// not named in ruleIds keep their existing relative order.
+func reorderNsxvNatRules(edgeGateway govcd.EdgeGateway, ruleIds []string, belowRuleId string) ([]*types.EdgeNatRule, error) {
+	allRules, err := edgeGateway.GetNsxvNatRules()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving current NAT ruleset: %s", err)
+	}
+
+	byId := make(map[string]*types.EdgeNatRule, len(allRules))
+	for _, rule := range allRules {
+		byId[rule.ID] = rule
+	}
+
+	var selected []*types.EdgeNatRule
+	for _, id := range ruleIds {
+		rule, ok := byId[id]
+		if !ok {
+			return nil, fmt.Errorf("rule ID '%s' not found in edge gateway NAT ruleset", id)
+		}
+		selected = append(selected, rule)
+	}
+
+	var remaining []*types.EdgeNatRule
+	for _, rule := range allRules {
+		if isRuleIDSelected(rule.ID, ruleIds) {
+			continue
+		}
+		remaining = append(remaining, rule)
+	}
+
+	if belowRuleId == "" {
+		return append(selected, remaining...), nil
+	}
+
+	var result []*types.EdgeNatRule
+	inserted := false
+	for _, rule := range remaining {
+		if rule.ID == belowRuleId {
+			result = append(result, selected...)
+			inserted = true
+		}
+		result = append(result, rule)
+	}
+	if !inserted {
+		return nil, fmt.Errorf("below_rule_id '%s' not found in edge gateway NAT ruleset", belowRuleId)
+	}
+
+	return result, nil
+}
+
+// isRuleIDSelected reports whether needle is present in haystack.
+func isRuleIDSelected(needle string, haystack []string) bool {
+	for _, item := range haystack {
+		if item == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleIdsFromSchema converts the raw []interface{} backing a `rule_ids` TypeList into a []string.
+func ruleIdsFromSchema(raw []interface{}) []string {
+	result := make([]string, len(raw))
+	for i, v := range raw {
+		result[i] = v.(string)
+	}
+	return result
+}
@@ -0,0 +1,381 @@
+package vcd
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+
+	"github.com/vmware/go-vcloud-director/v2/govcd"
+	"github.com/vmware/go-vcloud-director/v2/types/v56"
+)
+
+// natRuleBlockSchema returns the schema shared by the `dnat` and `snat` rule blocks of
+// `vcd_nsxv_nat_ruleset`. It is a trimmed mirror of the individual `vcd_nsxv_dnat`/`vcd_nsxv_snat`
+// resource schemas: it leaves out the lifecycle fields (`org`/`vdc`/`edge_gateway`/
+// `above_rule_id`/`below_rule_id`) that apply to the ruleset as a whole, and has no `rule_tag`,
+// `rule_type`, address group, `policy_based_vpn_mode` or `firewall_match` fields.
+func natRuleBlockSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "NAT rule ID, populated once the rule exists on the edge gateway",
+		},
+		"network_name": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Org or external network name",
+		},
+		"network_type": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.StringInSlice([]string{"ext", "org"}, false),
+			Description:  "Network type. One of 'ext', 'org'",
+		},
+		"enabled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Whether the rule should be enabled. Default 'true'",
+		},
+		"logging_enabled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Whether logging should be enabled for this rule. Default 'false'",
+		},
+		"description": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "NAT rule description",
+		},
+		"original_address": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Original address or address range",
+		},
+		"translated_address": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Translated address or address range",
+		},
+		"protocol": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			DiffSuppressFunc: suppressWordToEmptyString("any"),
+			ValidateFunc:     validateNatProtocol,
+			Description:      "Protocol. Such as 'tcp', 'udp', 'icmp', 'any', or a numeric protocol number",
+		},
+		"icmp_type": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validateCase("lower"),
+			Description:  "ICMP type. Only supported when protocol is ICMP. Default 'any'",
+		},
+		"original_port": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			DiffSuppressFunc: suppressNatPortRangeNormalization,
+			ValidateFunc:     validateNatPortRange,
+			Description:      "Original port, a single port number, or a 'start-end' range",
+		},
+		"translated_port": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			DiffSuppressFunc: suppressNatPortRangeNormalization,
+			ValidateFunc:     validateNatPortRange,
+			Description:      "Translated port, a single port number, or a 'start-end' range",
+		},
+	}
+}
+
+// resourceVcdNsxvNatRuleset manages a whole batch of DNAT/SNAT rules on one edge gateway as a
+// single resource. Unlike `vcd_nsxv_dnat`/`vcd_nsxv_snat`, which issue one API call per rule, it
+// diffs the desired rule blocks against the live ruleset and writes them back with a single PUT,
+// which avoids both the per-rule round-trip cost and the edge gateway lock races that come with
+// managing tens of rules as independent resources.
+func resourceVcdNsxvNatRuleset() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVcdNsxvNatRulesetCreateUpdate,
+		Read:   resourceVcdNsxvNatRulesetRead,
+		Update: resourceVcdNsxvNatRulesetCreateUpdate,
+		Delete: resourceVcdNsxvNatRulesetDelete,
+
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"edge_gateway": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Edge gateway name in which NAT rules are located",
+			},
+			"preserve_unmanaged": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+				Description: "Whether NAT rules created outside Terraform are left untouched. When " +
+					"'false', any user rule not described by a `dnat`/`snat` block is deleted",
+			},
+			"dnat": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Resource{Schema: natRuleBlockSchema()},
+			},
+			"snat": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Resource{Schema: natRuleBlockSchema()},
+			},
+		},
+	}
+}
+
+// natRulesetDesiredRules builds the ordered []*types.EdgeNatRule that the ruleset resource wants
+// present on the edge gateway, `dnat` blocks first and then `snat` blocks. Each rule is built by
+// natRuleFromBlock, not by getDnatRule: it sets the same address, port and protocol fields, but no
+// rule tag, and does not resolve address groups.
+func natRulesetDesiredRules(d *schema.ResourceData, edgeGateway govcd.EdgeGateway) ([]*types.EdgeNatRule, error) {
+	var desired []*types.EdgeNatRule
+
+	for _, action := range []string{"dnat", "snat"} {
+		blocks := d.Get(action).([]interface{})
+		for _, raw := range blocks {
+			block := raw.(map[string]interface{})
+			rule, err := natRuleFromBlock(action, block, edgeGateway)
+			if err != nil {
+				return nil, fmt.Errorf("error building %s rule: %s", action, err)
+			}
+			desired = append(desired, rule)
+		}
+	}
+
+	return desired, nil
+}
+
+// natRuleFromBlock builds a single types.EdgeNatRule from one `dnat`/`snat` block of
+// `vcd_nsxv_nat_ruleset`, resolving the network reference the same way getDnatRule does.
+func natRuleFromBlock(action string, block map[string]interface{}, edgeGateway govcd.EdgeGateway) (*types.EdgeNatRule, error) {
+	vnicIndex, err := getvNicIndexFromNetworkNameType(
+		block["network_name"].(string), block["network_type"].(string), edgeGateway)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := &types.EdgeNatRule{
+		Action:            action,
+		Enabled:           block["enabled"].(bool),
+		LoggingEnabled:    block["logging_enabled"].(bool),
+		Description:       block["description"].(string),
+		Vnic:              vnicIndex,
+		OriginalAddress:   block["original_address"].(string),
+		Protocol:          block["protocol"].(string),
+		IcmpType:          block["icmp_type"].(string),
+		OriginalPort:      block["original_port"].(string),
+		TranslatedAddress: block["translated_address"].(string),
+		TranslatedPort:    block["translated_port"].(string),
+	}
+
+	if id, ok := block["id"].(string); ok && id != "" {
+		rule.ID = id
+	}
+
+	return rule, nil
+}
+
+func resourceVcdNsxvNatRulesetCreateUpdate(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
+	if err != nil {
+		return fmt.Errorf("unable to find edge gateway: %s", err)
+	}
+
+	desired, err := natRulesetDesiredRules(d, edgeGateway)
+	if err != nil {
+		return err
+	}
+
+	finalRules := desired
+	if d.Get("preserve_unmanaged").(bool) {
+		liveRules, err := edgeGateway.GetNsxvNatRules()
+		if err != nil {
+			return fmt.Errorf("error reading current NAT ruleset: %s", err)
+		}
+
+		managed := make(map[string]bool, len(desired))
+		for _, rule := range desired {
+			if rule.ID != "" {
+				managed[rule.ID] = true
+			}
+		}
+
+		for _, rule := range liveRules {
+			if rule.RuleType == "user" && !managed[rule.ID] {
+				finalRules = append(finalRules, rule)
+			}
+		}
+	}
+
+	if err := withNatRetry(vcdClient.MaxRetryTimeout, func() error {
+		return edgeGateway.UpdateNsxvNatRules(finalRules)
+	}); err != nil {
+		return fmt.Errorf("error writing NAT ruleset: %s", err)
+	}
+
+	d.SetId(edgeGateway.EdgeGateway.Name)
+	return resourceVcdNsxvNatRulesetRead(d, meta)
+}
+
+func resourceVcdNsxvNatRulesetRead(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
+	if err != nil {
+		d.SetId("")
+		return nil
+	}
+
+	liveRules, err := edgeGateway.GetNsxvNatRules()
+	if err != nil {
+		return fmt.Errorf("error reading NAT rules: %s", err)
+	}
+
+	dnatBlocks, err := matchNatRulesetBlocks(liveRules, "dnat", d.Get("dnat").([]interface{}), edgeGateway)
+	if err != nil {
+		return err
+	}
+	snatBlocks, err := matchNatRulesetBlocks(liveRules, "snat", d.Get("snat").([]interface{}), edgeGateway)
+	if err != nil {
+		return err
+	}
+
+	if err := d.Set("dnat", dnatBlocks); err != nil {
+		return err
+	}
+	return d.Set("snat", snatBlocks)
+}
+
+// matchNatRulesetBlocks correlates configuredBlocks (the `dnat`/`snat` blocks already in state)
+// to liveRules by the block's own `id`, not by position - a rule inserted via the UI or another
+// resource, or the edge gateway simply reordering its ruleset on write, must not shift which live
+// rule a block picks up. A configured block's recorded `id` is looked up directly in liveRules.
+//
+// The one case with no `id` to correlate by is the read that runs immediately after this
+// resource's own Create, before any ID has ever been written to state. For those blocks (and for
+// a block whose recorded `id` no longer exists live, e.g. it was deleted out from under Terraform)
+// this falls back to claiming the next live rule of the same action that no ID lookup has already
+// claimed, in configured order - the same contiguous-front assumption Create relies on when it
+// writes `dnat` then `snat` as one run ahead of any preserve_unmanaged leftovers.
+func matchNatRulesetBlocks(liveRules []*types.EdgeNatRule, action string, configuredBlocks []interface{}, edgeGateway govcd.EdgeGateway) ([]map[string]interface{}, error) {
+	var liveOfAction []*types.EdgeNatRule
+	liveByID := make(map[string]*types.EdgeNatRule)
+	for _, rule := range liveRules {
+		if rule.RuleType != "user" || rule.Action != action {
+			continue
+		}
+		liveOfAction = append(liveOfAction, rule)
+		liveByID[rule.ID] = rule
+	}
+
+	claimed := make(map[string]bool, len(liveOfAction))
+	nextUnclaimed := 0
+	takeNextUnclaimed := func() *types.EdgeNatRule {
+		for nextUnclaimed < len(liveOfAction) {
+			candidate := liveOfAction[nextUnclaimed]
+			nextUnclaimed++
+			if !claimed[candidate.ID] {
+				return candidate
+			}
+		}
+		return nil
+	}
+
+	var blocks []map[string]interface{}
+	for _, raw := range configuredBlocks {
+		configured := raw.(map[string]interface{})
+
+		var rule *types.EdgeNatRule
+		if id, ok := configured["id"].(string); ok && id != "" {
+			rule = liveByID[id]
+		}
+		if rule == nil {
+			rule = takeNextUnclaimed()
+		}
+		if rule == nil {
+			continue
+		}
+		claimed[rule.ID] = true
+
+		networkName, networkType, err := getNetworkNameTypeFromVnicIndex(*rule.Vnic, edgeGateway)
+		if err != nil {
+			return nil, err
+		}
+
+		blocks = append(blocks, map[string]interface{}{
+			"id":                 rule.ID,
+			"network_name":       networkName,
+			"network_type":       networkType,
+			"enabled":            rule.Enabled,
+			"logging_enabled":    rule.LoggingEnabled,
+			"description":        rule.Description,
+			"original_address":   rule.OriginalAddress,
+			"translated_address": rule.TranslatedAddress,
+			"protocol":           rule.Protocol,
+			"icmp_type":          rule.IcmpType,
+			"original_port":      rule.OriginalPort,
+			"translated_port":    rule.TranslatedPort,
+		})
+	}
+
+	return blocks, nil
+}
+
+// resourceVcdNsxvNatRulesetDelete removes every rule this resource manages. When
+// `preserve_unmanaged` is set (the default) rules outside this resource's `dnat`/`snat` blocks are
+// never touched, so deletion only drops the managed subset.
+func resourceVcdNsxvNatRulesetDelete(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
+	if err != nil {
+		return fmt.Errorf("unable to find edge gateway: %s", err)
+	}
+
+	desired, err := natRulesetDesiredRules(d, edgeGateway)
+	if err != nil {
+		return err
+	}
+	managed := make(map[string]bool, len(desired))
+	for _, rule := range desired {
+		if rule.ID != "" {
+			managed[rule.ID] = true
+		}
+	}
+
+	liveRules, err := edgeGateway.GetNsxvNatRules()
+	if err != nil {
+		return fmt.Errorf("error reading current NAT ruleset: %s", err)
+	}
+
+	var remaining []*types.EdgeNatRule
+	for _, rule := range liveRules {
+		if rule.RuleType == "user" && managed[rule.ID] {
+			continue
+		}
+		remaining = append(remaining, rule)
+	}
+
+	return withNatRetry(vcdClient.MaxRetryTimeout, func() error {
+		return edgeGateway.UpdateNsxvNatRules(remaining)
+	})
+}
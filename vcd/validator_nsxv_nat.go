@@ -0,0 +1,136 @@
+package vcd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// natAliasedProtocolNumbers maps the numeric IANA protocol numbers that already have a dedicated
+// named value in this schema. Accepting them as numbers too would let two different values apply
+// to the same rule, so they are rejected in favor of the name.
+var natAliasedProtocolNumbers = map[string]string{
+	"1":  "icmp",
+	"6":  "tcp",
+	"17": "udp",
+}
+
+// validateNatProtocol accepts 'tcp', 'udp', 'icmp', 'any', or a numeric IANA protocol number in
+// the 1-252 range, following the pattern used for the ncloud ACG rule `protocol` field. Numbers
+// that alias one of the named protocols are rejected so that a rule cannot be expressed two
+// different ways.
+func validateNatProtocol(i interface{}, k string) ([]string, []error) {
+	value := i.(string)
+
+	switch value {
+	case "tcp", "udp", "icmp", "any", "":
+		return nil, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, []error{fmt.Errorf(
+			"%q must be one of 'tcp', 'udp', 'icmp', 'any', or a numeric protocol number 1-252, got: %s", k, value)}
+	}
+
+	if number < 1 || number > 252 {
+		return nil, []error{fmt.Errorf("%q numeric protocol must be in the range 1-252, got: %d", k, number)}
+	}
+
+	if name, ok := natAliasedProtocolNumbers[strconv.Itoa(number)]; ok {
+		return nil, []error{fmt.Errorf("%q: use '%s' instead of numeric protocol %s", k, name, value)}
+	}
+
+	return nil, nil
+}
+
+// validateNatPortRange accepts 'any', a single port number, or a 'start-end' range, for the
+// original_port/translated_port fields.
+func validateNatPortRange(i interface{}, k string) ([]string, []error) {
+	value := i.(string)
+
+	if value == "" || value == "any" {
+		return nil, nil
+	}
+
+	start, end, err := parseNatPortRange(value)
+	if err != nil {
+		return nil, []error{fmt.Errorf("%q: %s", k, err)}
+	}
+
+	if start < 1 || start > 65535 || end < 1 || end > 65535 {
+		return nil, []error{fmt.Errorf("%q ports must be in the range 1-65535, got: %s", k, value)}
+	}
+	if end < start {
+		return nil, []error{fmt.Errorf("%q range end must not be lower than its start, got: %s", k, value)}
+	}
+
+	return nil, nil
+}
+
+// parseNatPortRange parses "port" or "start-end" into numeric bounds, returning the same value
+// for start and end when a single port is given.
+func parseNatPortRange(value string) (start, end int, err error) {
+	parts := strings.SplitN(value, "-", 2)
+
+	start, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("must be 'any', a port number, or a 'start-end' range, got: %s", value)
+	}
+
+	if len(parts) == 1 {
+		return start, start, nil
+	}
+
+	end, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("must be 'any', a port number, or a 'start-end' range, got: %s", value)
+	}
+
+	return start, end, nil
+}
+
+// suppressNatPortRangeNormalization treats a single-port range such as "80-80" as equivalent to
+// "80", so that a plan reading one back from the API in the other form does not show a diff. It
+// also suppresses the empty-vs-'any' diff the other NAT fields handle with suppressWordToEmptyString.
+func suppressNatPortRangeNormalization(k, old, new string, d *schema.ResourceData) bool {
+	if old == new {
+		return true
+	}
+	if (old == "" && new == "any") || (old == "any" && new == "") {
+		return true
+	}
+
+	oldStart, oldEnd, oldErr := parseNatPortRange(old)
+	newStart, newEnd, newErr := parseNatPortRange(new)
+	if oldErr != nil || newErr != nil {
+		return false
+	}
+
+	return oldStart == newStart && oldEnd == newEnd
+}
+
+// validateNatRuleProtocolFields enforces the cross-field rules that a single ValidateFunc cannot
+// express: `icmp_type` only makes sense when `protocol` is 'icmp', and port fields only make sense
+// for 'tcp'/'udp'/'any'.
+func validateNatRuleProtocolFields(d *schema.ResourceDiff, meta interface{}) error {
+	protocol := d.Get("protocol").(string)
+
+	if icmpType := d.Get("icmp_type").(string); icmpType != "" && protocol != "icmp" {
+		return fmt.Errorf("'icmp_type' can only be set when 'protocol' is 'icmp', got protocol: %s", protocol)
+	}
+
+	if protocol == "tcp" || protocol == "udp" || protocol == "any" || protocol == "" {
+		return nil
+	}
+
+	for _, key := range []string{"original_port", "translated_port"} {
+		if port := d.Get(key).(string); port != "" && port != "any" {
+			return fmt.Errorf("'%s' can only be set when 'protocol' is 'tcp', 'udp' or 'any', got protocol: %s", key, protocol)
+		}
+	}
+
+	return nil
+}